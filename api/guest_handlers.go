@@ -0,0 +1,34 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ghostec/Will.IAM/usecases"
+	"github.com/topfreegames/extensions/middleware"
+)
+
+// guestPermissionsHandler lists the permissions attached to the guest role,
+// the read-only surface teams can publish without minting keypairs.
+// Permissions that reach the guest service account through role bindings
+// are not listed.
+func guestPermissionsHandler(
+	rolesUC usecases.Roles,
+) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := middleware.GetLogger(r.Context())
+		permissions, err := rolesUC.GuestPermissions()
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		bts, err := json.Marshal(permissions)
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		WriteBytes(w, 200, bts)
+	}
+}
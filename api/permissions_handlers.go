@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/ghostec/Will.IAM/usecases"
+	"github.com/topfreegames/Will.IAM/constants"
+	"github.com/topfreegames/Will.IAM/models"
+	"github.com/topfreegames/extensions/middleware"
+)
+
+func permissionsListHandler(
+	permsUC usecases.Permissions,
+) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := middleware.GetLogger(r.Context())
+		caller := authenticatedServiceAccount(r)
+		if caller == nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rhs, err := permsUC.ContextsForPermission(
+			caller, models.BuildAction("List"), constants.AppInfo.Name,
+		)
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if len(rhs) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		permissionSl, err := permsUC.ListForContexts(rhs)
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		bts, err := keepJSONFieldsBytes(
+			permissionSl, "id", "roleId", "service", "ownershipLevel",
+			"action", "resourceHierarchy", "alias",
+		)
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		WriteBytes(w, 200, bts)
+	}
+}
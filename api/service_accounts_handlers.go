@@ -7,6 +7,8 @@ import (
 
 	"github.com/ghostec/Will.IAM/usecases"
 	"github.com/gorilla/mux"
+	"github.com/topfreegames/Will.IAM/constants"
+	"github.com/topfreegames/Will.IAM/models"
 	"github.com/topfreegames/extensions/middleware"
 )
 
@@ -93,11 +95,28 @@ func serviceAccountsCreateHandler(
 }
 
 func serviceAccountsListHandler(
-	sasUC usecases.ServiceAccounts,
+	sasUC usecases.ServiceAccounts, permsUC usecases.Permissions,
 ) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		l := middleware.GetLogger(r.Context())
-		saSl, err := sasUC.List()
+		caller := authenticatedServiceAccount(r)
+		if caller == nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rhs, err := permsUC.ContextsForPermission(
+			caller, models.BuildAction("List"), constants.AppInfo.Name,
+		)
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if len(rhs) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		saSl, err := sasUC.ListForContexts(rhs)
 		if err != nil {
 			l.Error(err)
 			w.WriteHeader(http.StatusInternalServerError)
@@ -112,3 +131,91 @@ func serviceAccountsListHandler(
 		WriteBytes(w, 200, bts)
 	}
 }
+
+func serviceAccountsAccessHandler(
+	sasUC usecases.ServiceAccounts,
+) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := middleware.GetLogger(r.Context())
+		saID := mux.Vars(r)["id"]
+		report, err := sasUC.Analyze(saID)
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		bts, err := json.Marshal(report)
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		WriteBytes(w, 200, bts)
+	}
+}
+
+func serviceAccountsAccessCheckHandler(
+	sasUC usecases.ServiceAccounts,
+) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := middleware.GetLogger(r.Context())
+		saID := mux.Vars(r)["id"]
+		body, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		m := map[string][]string{}
+		if err := json.Unmarshal(body, &m); err != nil {
+			Write(w, http.StatusUnprocessableEntity,
+				`{ "error": { "permissions": "invalid" } }`)
+			return
+		}
+		permissionsStrings, ok := m["permissions"]
+		if !ok || len(permissionsStrings) == 0 {
+			Write(w, http.StatusUnprocessableEntity,
+				`{ "error": { "permissions": "required" } }`)
+			return
+		}
+		results, err := sasUC.CheckAccess(saID, permissionsStrings)
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		bts, err := json.Marshal(results)
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		WriteBytes(w, 200, bts)
+	}
+}
+
+func serviceAccountsRotateKeyPairHandler(
+	sasUC usecases.ServiceAccounts,
+) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := middleware.GetLogger(r.Context())
+		saID := mux.Vars(r)["id"]
+		keyID, keySecret, err := sasUC.RotateKeyPair(saID)
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		bts, err := json.Marshal(map[string]string{
+			"keyId":     keyID,
+			"keySecret": keySecret,
+		})
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		WriteBytes(w, 200, bts)
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/ghostec/Will.IAM/usecases"
+	"github.com/topfreegames/extensions/middleware"
+)
+
+func signingKeysCreateHandler(
+	sksUC usecases.SigningKeys,
+) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := middleware.GetLogger(r.Context())
+		body, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		m := map[string]string{}
+		if err := json.Unmarshal(body, &m); err != nil {
+			Write(w, http.StatusUnprocessableEntity,
+				`{ "error": { "body": "invalid" } }`)
+			return
+		}
+		for _, field := range []string{"algorithm", "publicKey", "privateKey", "notAfter"} {
+			if m[field] == "" {
+				Write(w, http.StatusUnprocessableEntity,
+					fmt.Sprintf(`{ "error": { "%s": "required" } }`, field))
+				return
+			}
+		}
+		notAfter, err := time.Parse(time.RFC3339, m["notAfter"])
+		if err != nil {
+			Write(w, http.StatusUnprocessableEntity,
+				`{ "error": { "notAfter": "invalid" } }`)
+			return
+		}
+		notBefore := time.Now()
+		if m["notBefore"] != "" {
+			notBefore, err = time.Parse(time.RFC3339, m["notBefore"])
+			if err != nil {
+				Write(w, http.StatusUnprocessableEntity,
+					`{ "error": { "notBefore": "invalid" } }`)
+				return
+			}
+		}
+		sk, err := sksUC.Add(
+			m["algorithm"], m["publicKey"], m["privateKey"], notBefore, notAfter,
+		)
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		bts, err := json.Marshal(sk)
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		WriteBytes(w, http.StatusCreated, bts)
+	}
+}
+
+func jwksHandler(
+	sksUC usecases.SigningKeys,
+) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := middleware.GetLogger(r.Context())
+		keys, err := sksUC.JWKS()
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		jwks := make([]map[string]string, len(keys))
+		for i, key := range keys {
+			jwks[i] = map[string]string{
+				"kid":       key.ID,
+				"alg":       key.Algorithm,
+				"publicKey": key.PublicKey,
+			}
+		}
+		bts, err := json.Marshal(map[string]interface{}{"keys": jwks})
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		WriteBytes(w, 200, bts)
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/ghostec/Will.IAM/usecases"
+	"github.com/topfreegames/Will.IAM/models"
+	"github.com/topfreegames/extensions/middleware"
+)
+
+// keyPairExchangeHandler trades a service account's keyId/keySecret for a
+// short-lived JWT: the "mint on successful auth" half of the token flow,
+// letting every later request prove its identity via `Authorization: Bearer`
+// (verified offline by withBearerAuth) instead of resending the secret.
+func keyPairExchangeHandler(
+	sasUC usecases.ServiceAccounts, tokensUC usecases.Tokens,
+) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := middleware.GetLogger(r.Context())
+		body, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		m := map[string]string{}
+		if err := json.Unmarshal(body, &m); err != nil {
+			Write(w, http.StatusUnprocessableEntity,
+				`{ "error": { "keyId": "invalid" } }`)
+			return
+		}
+		keyID, keySecret := m["keyId"], m["keySecret"]
+		if keyID == "" || keySecret == "" {
+			Write(w, http.StatusUnprocessableEntity,
+				`{ "error": { "keyId": "required", "keySecret": "required" } }`)
+			return
+		}
+		sa, err := sasUC.Authenticate(keyID, keySecret)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		report, err := sasUC.Analyze(sa.ID)
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		scope := make([]string, len(report.Entries))
+		for i, e := range report.Entries {
+			scope[i] = models.Permission{
+				Service: e.Service, OwnershipLevel: e.OwnershipLevel,
+				Action: e.Action, ResourceHierarchy: e.ResourceHierarchy,
+			}.String()
+		}
+		token, err := tokensUC.Mint(sa, scope)
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		bts, err := json.Marshal(map[string]string{"token": token})
+		if err != nil {
+			l.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		WriteBytes(w, 200, bts)
+	}
+}
@@ -0,0 +1,10 @@
+package models
+
+// GroupMembership records that a service account belongs to a group, the
+// missing link permission resolution needs to honor a RoleBinding whose
+// SubjectType is SubjectTypes.Group.
+type GroupMembership struct {
+	ID               string `json:"id" pg:"id"`
+	GroupID          string `json:"groupId" pg:"group_id"`
+	ServiceAccountID string `json:"serviceAccountId" pg:"service_account_id"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// RoleNames are the reserved, built-in roles every Will.IAM installation
+// has, created idempotently by usecases.Roles.EnsureBuiltins on boot.
+var RoleNames = struct {
+	Root  string
+	Guest string
+}{
+	Root:  "root",
+	Guest: "guest",
+}
+
+// Role groups a set of Permissions that can be bound to subjects via
+// RoleBinding (or, historically, attached directly via Permission.RoleID).
+type Role struct {
+	ID   string `json:"id" pg:"id"`
+	Name string `json:"name" pg:"name"`
+}
+
+// GuestServiceAccountID is the reserved id of the always-present Service
+// Account usecases.Roles.EnsureBuiltins provisions and binds to the guest
+// Role, attached to requests that opt into guest fallback instead of a
+// real session/keypair.
+const GuestServiceAccountID = "00000000-0000-0000-0000-000000000000"
@@ -0,0 +1,42 @@
+package models
+
+// SubjectType identifies what kind of subject a RoleBinding grants a role to
+type SubjectType string
+
+// SubjectTypes are all possible kinds of RoleBinding subject
+var SubjectTypes = struct {
+	ServiceAccount SubjectType
+	Group          SubjectType
+	Email          SubjectType
+}{
+	ServiceAccount: "service_account",
+	Group:          "group",
+	Email:          "email",
+}
+
+// RoleBinding grants a Role to a subject (a service account, a group or an
+// email), optionally narrowing the role's permissions down to a subtree of
+// ResourceHierarchy at bind time instead of copying scoped permission rows.
+type RoleBinding struct {
+	ID          string            `json:"id" pg:"id"`
+	RoleID      string            `json:"roleId" pg:"role_id"`
+	SubjectType SubjectType       `json:"subjectType" pg:"subject_type"`
+	SubjectID   string            `json:"subjectId" pg:"subject_id"`
+	Scope       ResourceHierarchy `json:"scope" pg:"scope"`
+}
+
+// Intersect narrows p's ResourceHierarchy down to rb's Scope, returning
+// false if rb doesn't apply to p at all (no overlap between the two).
+func (rb RoleBinding) Intersect(p Permission) (Permission, bool) {
+	if rb.Scope == "" || rb.Scope.All() {
+		return p, true
+	}
+	if rb.Scope.Contains(p.ResourceHierarchy) {
+		return p, true
+	}
+	if p.ResourceHierarchy.Contains(rb.Scope) {
+		p.ResourceHierarchy = rb.Scope
+		return p, true
+	}
+	return Permission{}, false
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// SigningKey is one RSA key pair usable to sign or verify Tokens, keyed by
+// ID so a JWT's `kid` header can select the exact key that minted it. This
+// is what enables zero-downtime rotation: a new key can be added and start
+// signing while old tokens still verify against their own key, which Verify
+// looks up by ID whatever its NotAfter, until the tokens themselves expire.
+type SigningKey struct {
+	ID         string    `json:"id" pg:"id"`
+	Algorithm  string    `json:"algorithm" pg:"algorithm"`
+	PublicKey  string    `json:"publicKey" pg:"public_key"`
+	PrivateKey string    `json:"-" pg:"private_key"`
+	NotBefore  time.Time `json:"notBefore" pg:"not_before"`
+	NotAfter   time.Time `json:"notAfter" pg:"not_after"`
+}
+
+// Active reports whether at falls within [NotBefore, NotAfter), i.e.
+// whether this key should currently be used to sign new tokens.
+func (sk SigningKey) Active(at time.Time) bool {
+	return !at.Before(sk.NotBefore) && at.Before(sk.NotAfter)
+}
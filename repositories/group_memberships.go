@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"github.com/ghostec/Will.IAM/models"
+)
+
+// GroupMemberships repository
+type GroupMemberships interface {
+	ForServiceAccount(serviceAccountID string) ([]models.GroupMembership, error)
+	Create(*models.GroupMembership) error
+	Delete(id string) error
+}
+
+type groupMemberships struct {
+	storage *Storage
+}
+
+// ForServiceAccount lists every group serviceAccountID belongs to, so
+// permission resolution can also gather role_bindings bound to those groups.
+func (gms groupMemberships) ForServiceAccount(
+	serviceAccountID string,
+) ([]models.GroupMembership, error) {
+	gmSl := []models.GroupMembership{}
+	if _, err := gms.storage.PG.DB.Query(
+		&gmSl,
+		`SELECT id, group_id, service_account_id FROM group_memberships
+		WHERE service_account_id = ?`,
+		serviceAccountID,
+	); err != nil {
+		return nil, err
+	}
+	return gmSl, nil
+}
+
+func (gms groupMemberships) Create(gm *models.GroupMembership) error {
+	_, err := gms.storage.PG.DB.Query(
+		gm, `INSERT INTO group_memberships (id, group_id, service_account_id)
+		VALUES (?id, ?group_id, ?service_account_id) RETURNING id`, gm,
+	)
+	return err
+}
+
+func (gms groupMemberships) Delete(id string) error {
+	_, err := gms.storage.PG.DB.Exec(
+		`DELETE FROM group_memberships WHERE id = ?`, id,
+	)
+	return err
+}
+
+// NewGroupMemberships groupMemberships ctor
+func NewGroupMemberships(s *Storage) GroupMemberships {
+	return &groupMemberships{storage: s}
+}
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/ghostec/Will.IAM/models"
+)
+
+// RoleBindings repository
+type RoleBindings interface {
+	Create(*models.RoleBinding) error
+	List(roleID string) ([]models.RoleBinding, error)
+	ForSubject(subjectType models.SubjectType, subjectID string) ([]models.RoleBinding, error)
+	Delete(id string) error
+}
+
+type roleBindings struct {
+	storage *Storage
+}
+
+func (rbs roleBindings) Create(rb *models.RoleBinding) error {
+	_, err := rbs.storage.PG.DB.Query(
+		rb, `INSERT INTO role_bindings (id, role_id, subject_type,
+		subject_id, scope) VALUES (?id, ?role_id, ?subject_type, ?subject_id,
+		?scope) RETURNING id`, rb,
+	)
+	return err
+}
+
+func (rbs roleBindings) List(roleID string) ([]models.RoleBinding, error) {
+	rbSl := []models.RoleBinding{}
+	if _, err := rbs.storage.PG.DB.Query(
+		&rbSl,
+		`SELECT id, role_id, subject_type, subject_id, scope
+		FROM role_bindings WHERE role_id = ?`,
+		roleID,
+	); err != nil {
+		return nil, err
+	}
+	return rbSl, nil
+}
+
+// ForSubject retrieves every RoleBinding granted directly to subjectID,
+// which the caller then joins against roles/permissions to resolve access.
+func (rbs roleBindings) ForSubject(
+	subjectType models.SubjectType, subjectID string,
+) ([]models.RoleBinding, error) {
+	rbSl := []models.RoleBinding{}
+	if _, err := rbs.storage.PG.DB.Query(
+		&rbSl,
+		`SELECT id, role_id, subject_type, subject_id, scope
+		FROM role_bindings WHERE subject_type = ? AND subject_id = ?`,
+		subjectType, subjectID,
+	); err != nil {
+		return nil, err
+	}
+	return rbSl, nil
+}
+
+func (rbs roleBindings) Delete(id string) error {
+	_, err := rbs.storage.PG.DB.Exec(
+		`DELETE FROM role_bindings WHERE id = ?`, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete role binding %s: %v", id, err)
+	}
+	return nil
+}
+
+// NewRoleBindings roleBindings ctor
+func NewRoleBindings(s *Storage) RoleBindings {
+	return &roleBindings{storage: s}
+}
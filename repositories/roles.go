@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ghostec/Will.IAM/models"
+)
+
+// Roles repository
+type Roles interface {
+	Get(string) (*models.Role, error)
+	List() ([]models.Role, error)
+	ListForResourceHierarchies(
+		[]models.ResourceHierarchy,
+	) ([]models.Role, error)
+	ForName(string) (*models.Role, error)
+	Create(*models.Role) error
+}
+
+type roles struct {
+	storage *Storage
+}
+
+func (rs roles) Get(id string) (*models.Role, error) {
+	role := new(models.Role)
+	if _, err := rs.storage.PG.DB.Query(
+		role, `SELECT id, name FROM roles WHERE id = ?`, id,
+	); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (rs roles) List() ([]models.Role, error) {
+	roleSl := []models.Role{}
+	if _, err := rs.storage.PG.DB.Query(
+		&roleSl, `SELECT id, name FROM roles ORDER BY name`,
+	); err != nil {
+		return nil, err
+	}
+	return roleSl, nil
+}
+
+// ListForResourceHierarchies retrieves roles that hold a permission whose
+// ResourceHierarchy falls under any of rhs, the same filter
+// serviceAccounts.ListForResourceHierarchies applies, scoped to roles.
+func (rs roles) ListForResourceHierarchies(
+	rhs []models.ResourceHierarchy,
+) ([]models.Role, error) {
+	roleSl := []models.Role{}
+	if len(rhs) == 0 {
+		return roleSl, nil
+	}
+	wheres := make([]string, len(rhs))
+	args := make([]interface{}, len(rhs))
+	for i, rh := range rhs {
+		if rh.All() {
+			wheres[i] = "true"
+			continue
+		}
+		if strings.HasSuffix(string(rh), "::*") {
+			wheres[i] = fmt.Sprintf("p.resource_hierarchy LIKE ?%d || '%%'", i)
+			args[i] = strings.TrimSuffix(string(rh), "*")
+			continue
+		}
+		wheres[i] = fmt.Sprintf(
+			"(p.resource_hierarchy = ?%d OR p.resource_hierarchy LIKE ?%d || '::%%')",
+			i, i,
+		)
+		args[i] = string(rh)
+	}
+	query := fmt.Sprintf(
+		`SELECT DISTINCT r.id, r.name FROM roles r
+		JOIN permissions p ON p.role_id = r.id
+		WHERE %s ORDER BY r.name`,
+		strings.Join(wheres, " OR "),
+	)
+	if _, err := rs.storage.PG.DB.Query(&roleSl, query, args...); err != nil {
+		return nil, err
+	}
+	return roleSl, nil
+}
+
+// ForName retrieves a Role by its (unique) name, used to look up the
+// built-in root/guest roles without hardcoding their generated ids.
+func (rs roles) ForName(name string) (*models.Role, error) {
+	role := new(models.Role)
+	if _, err := rs.storage.PG.DB.Query(
+		role, `SELECT id, name FROM roles WHERE name = ? LIMIT 1`, name,
+	); err != nil {
+		return nil, err
+	}
+	if role.ID == "" {
+		return nil, nil
+	}
+	return role, nil
+}
+
+func (rs roles) Create(role *models.Role) error {
+	_, err := rs.storage.PG.DB.Query(
+		role, `INSERT INTO roles (id, name) VALUES (?id, ?name)
+		ON CONFLICT (name) DO UPDATE SET name = roles.name
+		RETURNING id`, role,
+	)
+	return err
+}
+
+// NewRoles roles ctor
+func NewRoles(s *Storage) Roles {
+	return &roles{storage: s}
+}
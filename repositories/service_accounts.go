@@ -2,18 +2,24 @@ package repositories
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/ghostec/Will.IAM/models"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // ServiceAccounts repository
 type ServiceAccounts interface {
 	Get(string) (*models.ServiceAccount, error)
 	List() ([]models.ServiceAccount, error)
+	ListForResourceHierarchies(
+		[]models.ResourceHierarchy,
+	) ([]models.ServiceAccount, error)
 	Search(string) ([]models.ServiceAccount, error)
 	ForEmail(string) (*models.ServiceAccount, error)
 	ForKeyPair(string, string) (*models.ServiceAccount, error)
 	Create(*models.ServiceAccount) error
+	Update(*models.ServiceAccount) error
 }
 
 type serviceAccounts struct {
@@ -46,6 +52,50 @@ func (sas serviceAccounts) List() ([]models.ServiceAccount, error) {
 	return saSl, nil
 }
 
+// ListForResourceHierarchies retrieves service accounts that hold a role
+// binding (directly by id, or by email) scoped under any of rhs, using the
+// same `::` prefix convention models.ResourceHierarchy.PermissionMatches
+// relies on. An empty rhs matches nothing, since it represents "no
+// permission context" rather than "any".
+func (sas serviceAccounts) ListForResourceHierarchies(
+	rhs []models.ResourceHierarchy,
+) ([]models.ServiceAccount, error) {
+	saSl := []models.ServiceAccount{}
+	if len(rhs) == 0 {
+		return saSl, nil
+	}
+	wheres := make([]string, len(rhs))
+	args := make([]interface{}, len(rhs))
+	for i, rh := range rhs {
+		if rh.All() {
+			wheres[i] = "true"
+			continue
+		}
+		if strings.HasSuffix(string(rh), "::*") {
+			wheres[i] = fmt.Sprintf("rb.scope LIKE ?%d || '%%'", i)
+			args[i] = strings.TrimSuffix(string(rh), "*")
+			continue
+		}
+		wheres[i] = fmt.Sprintf(
+			"(rb.scope = ?%d OR rb.scope LIKE ?%d || '::%%')", i, i,
+		)
+		args[i] = string(rh)
+	}
+	query := fmt.Sprintf(
+		`SELECT DISTINCT sa.id, sa.name, sa.email, sa.picture
+		FROM service_accounts sa
+		JOIN role_bindings rb ON
+			(rb.subject_type = 'service_account' AND rb.subject_id = sa.id) OR
+			(rb.subject_type = 'email' AND rb.subject_id = sa.email)
+		WHERE %s ORDER BY sa.created_at DESC`,
+		strings.Join(wheres, " OR "),
+	)
+	if _, err := sas.storage.PG.DB.Query(&saSl, query, args...); err != nil {
+		return nil, err
+	}
+	return saSl, nil
+}
+
 func (sas serviceAccounts) Search(
 	term string,
 ) ([]models.ServiceAccount, error) {
@@ -79,34 +129,74 @@ func (sas serviceAccounts) ForEmail(
 	return sa, nil
 }
 
-// ForKeyPair retrieves Service Account corresponding
+// ForKeyPair retrieves the Service Account for keyID and verifies keySecret
+// against its bcrypt hash. keyID alone drives the lookup so the query never
+// touches the secret; "not found" is returned for both an unknown keyID and
+// a wrong keySecret, so the two can't be told apart from the response.
 func (sas serviceAccounts) ForKeyPair(
 	keyID, keySecret string,
 ) (*models.ServiceAccount, error) {
-	sa := []*models.ServiceAccount{}
+	sa := new(models.ServiceAccount)
 	if _, err := sas.storage.PG.DB.Query(
-		&sa, `SELECT id, name, key_id, key_secret, email, base_role_id
-		FROM service_accounts WHERE key_id = ? AND key_secret = ?`,
-		keyID, keySecret,
+		sa, `SELECT id, name, key_id, key_secret, key_secret_hash, email,
+		base_role_id FROM service_accounts WHERE key_id = ?`, keyID,
 	); err != nil {
 		return nil, err
 	}
-	if len(sa) == 0 {
+	if sa.ID == "" {
 		return nil, fmt.Errorf("service account not found")
 	}
-	return sa[0], nil
+	if sa.KeySecretHash == "" {
+		if sa.KeySecret != keySecret {
+			return nil, fmt.Errorf("service account not found")
+		}
+		go sas.rehashLegacyKeySecret(sa.ID, keySecret)
+		return sa, nil
+	}
+	if err := bcrypt.CompareHashAndPassword(
+		[]byte(sa.KeySecretHash), []byte(keySecret),
+	); err != nil {
+		return nil, fmt.Errorf("service account not found")
+	}
+	return sa, nil
+}
+
+// rehashLegacyKeySecret migrates a row still on the plaintext key_secret
+// column to key_secret_hash now that keySecret has been proven correct.
+// Fire-and-forget: a failure here just leaves the row legacy for next login.
+func (sas serviceAccounts) rehashLegacyKeySecret(id, keySecret string) {
+	hash, err := bcrypt.GenerateFromPassword(
+		[]byte(keySecret), bcrypt.DefaultCost,
+	)
+	if err != nil {
+		return
+	}
+	sas.storage.PG.DB.Exec(
+		`UPDATE service_accounts SET key_secret_hash = ?, key_secret = NULL
+		WHERE id = ?`, string(hash), id,
+	)
 }
 
 func (sas serviceAccounts) Create(sa *models.ServiceAccount) error {
 	_, err := sas.storage.PG.DB.Query(
-		sa, `INSERT INTO service_accounts (id, name, email, key_id, key_secret,
-		base_role_id) VALUES (?id, ?name, ?email, ?key_id, ?key_secret,
-		?base_role_id) ON CONFLICT (email) DO UPDATE
+		sa, `INSERT INTO service_accounts (id, name, email, key_id,
+		key_secret_hash, base_role_id) VALUES (?id, ?name, ?email, ?key_id,
+		?key_secret_hash, ?base_role_id) ON CONFLICT (email) DO UPDATE
 		SET picture = ?picture, updated_at = now() RETURNING id`, sa,
 	)
 	return err
 }
 
+// Update persists a Service Account's key pair, used by RotateKeyPair.
+func (sas serviceAccounts) Update(sa *models.ServiceAccount) error {
+	_, err := sas.storage.PG.DB.Query(
+		sa, `UPDATE service_accounts SET key_id = ?key_id,
+		key_secret_hash = ?key_secret_hash, updated_at = now()
+		WHERE id = ?id RETURNING id`, sa,
+	)
+	return err
+}
+
 // NewServiceAccounts serviceAccounts ctor
 func NewServiceAccounts(s *Storage) ServiceAccounts {
 	return &serviceAccounts{storage: s}
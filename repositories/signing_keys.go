@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"github.com/ghostec/Will.IAM/models"
+)
+
+// SigningKeys repository
+type SigningKeys interface {
+	Get(id string) (*models.SigningKey, error)
+	// Newest returns the currently Active key with the latest NotBefore,
+	// the one Tokens.Mint should sign new tokens with.
+	Newest() (*models.SigningKey, error)
+	List() ([]models.SigningKey, error)
+	Create(*models.SigningKey) error
+}
+
+type signingKeys struct {
+	storage *Storage
+}
+
+func (sks signingKeys) Get(id string) (*models.SigningKey, error) {
+	sk := new(models.SigningKey)
+	if _, err := sks.storage.PG.DB.Query(
+		sk, `SELECT id, algorithm, public_key, private_key, not_before,
+		not_after FROM signing_keys WHERE id = ?`, id,
+	); err != nil {
+		return nil, err
+	}
+	if sk.ID == "" {
+		return nil, nil
+	}
+	return sk, nil
+}
+
+func (sks signingKeys) Newest() (*models.SigningKey, error) {
+	sk := new(models.SigningKey)
+	if _, err := sks.storage.PG.DB.Query(
+		sk, `SELECT id, algorithm, public_key, private_key, not_before,
+		not_after FROM signing_keys
+		WHERE not_before <= now() AND not_after > now()
+		ORDER BY not_before DESC LIMIT 1`,
+	); err != nil {
+		return nil, err
+	}
+	if sk.ID == "" {
+		return nil, nil
+	}
+	return sk, nil
+}
+
+func (sks signingKeys) List() ([]models.SigningKey, error) {
+	skSl := []models.SigningKey{}
+	if _, err := sks.storage.PG.DB.Query(
+		&skSl, `SELECT id, algorithm, public_key, not_before, not_after
+		FROM signing_keys ORDER BY not_before DESC`,
+	); err != nil {
+		return nil, err
+	}
+	return skSl, nil
+}
+
+func (sks signingKeys) Create(sk *models.SigningKey) error {
+	_, err := sks.storage.PG.DB.Query(
+		sk, `INSERT INTO signing_keys (id, algorithm, public_key,
+		private_key, not_before, not_after) VALUES (?id, ?algorithm,
+		?public_key, ?private_key, ?not_before, ?not_after) RETURNING id`, sk,
+	)
+	return err
+}
+
+// NewSigningKeys signingKeys ctor
+func NewSigningKeys(s *Storage) SigningKeys {
+	return &signingKeys{storage: s}
+}
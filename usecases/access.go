@@ -0,0 +1,43 @@
+package usecases
+
+import (
+	"github.com/topfreegames/Will.IAM/models"
+	"github.com/topfreegames/Will.IAM/repositories"
+)
+
+// bindingsForServiceAccount gathers every role_binding that applies to sa:
+// bound directly to its id, to its email, or to any group it belongs to via
+// GroupMemberships. usecases.Permissions and usecases.ServiceAccounts both
+// resolve effective access through this single function, so the two can't
+// drift out of sync on what "what can this service account do" means.
+func bindingsForServiceAccount(
+	repo *repositories.All, sa *models.ServiceAccount,
+) ([]models.RoleBinding, error) {
+	bindings, err := repo.RoleBindings.ForSubject(
+		models.SubjectTypes.ServiceAccount, sa.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	emailBindings, err := repo.RoleBindings.ForSubject(
+		models.SubjectTypes.Email, sa.Email,
+	)
+	if err != nil {
+		return nil, err
+	}
+	bindings = append(bindings, emailBindings...)
+	memberships, err := repo.GroupMemberships.ForServiceAccount(sa.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, membership := range memberships {
+		groupBindings, err := repo.RoleBindings.ForSubject(
+			models.SubjectTypes.Group, membership.GroupID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		bindings = append(bindings, groupBindings...)
+	}
+	return bindings, nil
+}
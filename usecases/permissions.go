@@ -2,7 +2,9 @@ package usecases
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/google/uuid"
 	"github.com/topfreegames/Will.IAM/models"
 	"github.com/topfreegames/Will.IAM/repositories"
 )
@@ -10,10 +12,17 @@ import (
 // Permissions define entrypoints for Permissions actions
 type Permissions interface {
 	Get(string) (*models.Permission, error)
+	List() ([]models.Permission, error)
+	ListForContexts(
+		[]models.ResourceHierarchy,
+	) ([]models.Permission, error)
 	Delete(string) error
 	Create(*models.Permission) error
 	Attribute(*PermissionsAttribute) error
 	AttributeToEmails(*PermissionsAttributeToEmails) error
+	ContextsForPermission(
+		sa *models.ServiceAccount, action models.Action, service string,
+	) ([]models.ResourceHierarchy, error)
 	WithContext(context.Context) Permissions
 }
 
@@ -46,15 +55,39 @@ func (ps permissions) Get(id string) (*models.Permission, error) {
 	return ps.repo.Permissions.Get(id)
 }
 
+func (ps permissions) List() ([]models.Permission, error) {
+	return ps.repo.Permissions.List()
+}
+
+// ListForContexts narrows List down to the permissions reachable from rhs,
+// the same scoping usecases.ServiceAccounts.ListForContexts applies.
+func (ps permissions) ListForContexts(
+	rhs []models.ResourceHierarchy,
+) ([]models.Permission, error) {
+	return ps.repo.Permissions.ListForResourceHierarchies(rhs)
+}
+
 func (ps permissions) Delete(id string) error {
 	return ps.repo.Permissions.Delete(id)
 }
 
 func (ps permissions) Create(p *models.Permission) error {
+	if err := ps.refuseGuestOwnership(p.RoleID, p.OwnershipLevel); err != nil {
+		return err
+	}
 	return ps.repo.Permissions.Create(p)
 }
 
 func (ps permissions) Attribute(pa *PermissionsAttribute) error {
+	for _, roleID := range pa.RolesIDs {
+		for _, permission := range pa.Permissions {
+			if err := ps.refuseGuestOwnership(
+				roleID, permission.OwnershipLevel,
+			); err != nil {
+				return err
+			}
+		}
+	}
 	return ps.repo.WithPGTx(ps.ctx, func(repo *repositories.All) error {
 		for _, roleID := range pa.RolesIDs {
 			for _, permission := range pa.Permissions {
@@ -68,24 +101,151 @@ func (ps permissions) Attribute(pa *PermissionsAttribute) error {
 	})
 }
 
-func (ps permissions) AttributeToEmails(pa *PermissionsAttributeToEmails) error {
-	sas, err := ps.repo.ServiceAccounts.ForEmails(pa.Emails)
+// refuseGuestOwnership rejects granting the guest role an Owner (RO)
+// permission: guest is meant to publish a small read-only surface, and
+// owner rights would let an unauthenticated caller re-delegate access.
+func (ps permissions) refuseGuestOwnership(
+	roleID string, ownershipLevel models.OwnershipLevel,
+) error {
+	if ownershipLevel != models.OwnershipLevels.Owner {
+		return nil
+	}
+	role, err := ps.repo.Roles.Get(roleID)
+	if err != nil {
+		return err
+	}
+	if role != nil && role.Name == models.RoleNames.Guest {
+		return fmt.Errorf(
+			"guest role can't be granted an owner (RO) permission",
+		)
+	}
+	return nil
+}
+
+// refuseBindingGuestOwnership rejects binding roleID to a subject when
+// either side would hand the guest identity an Owner (RO) permission: roleID
+// is itself the guest role, or the binding's subject is the guest service
+// account. refuseGuestOwnership only ever saw permissions created directly
+// under a role named "guest"; RoleBindings.Create can just as easily bind
+// guest to a powerful role by id, bypassing that check entirely, so this
+// closes the same invariant at the binding layer.
+func refuseBindingGuestOwnership(
+	repo *repositories.All, roleID string,
+	subjectType models.SubjectType, subjectID string,
+) error {
+	isGuestSubject := subjectType == models.SubjectTypes.ServiceAccount &&
+		subjectID == models.GuestServiceAccountID
+	role, err := repo.Roles.Get(roleID)
 	if err != nil {
 		return err
 	}
+	isGuestRole := role != nil && role.Name == models.RoleNames.Guest
+	if !isGuestSubject && !isGuestRole {
+		return nil
+	}
+	rolePermissions, err := repo.Permissions.ForRole(roleID)
+	if err != nil {
+		return err
+	}
+	for _, p := range rolePermissions {
+		if p.OwnershipLevel == models.OwnershipLevels.Owner {
+			return fmt.Errorf(
+				"guest can't be bound to a role holding an owner (RO) permission",
+			)
+		}
+	}
+	return nil
+}
+
+// AttributeToEmails creates a single role holding pa.Permissions and binds
+// it to every email in pa.Emails via role_bindings, instead of copying a
+// permission row per email. Emails don't need to resolve to a service
+// account yet: the binding's subject is the email itself, and resolution
+// happens at permission-check time.
+func (ps permissions) AttributeToEmails(pa *PermissionsAttributeToEmails) error {
 	return ps.repo.WithPGTx(ps.ctx, func(repo *repositories.All) error {
-		for _, sa := range sas {
-			for _, permission := range pa.Permissions {
-				permission.RoleID = sa.BaseRoleID
-				if err := repo.Permissions.Create(&permission); err != nil {
-					return err
-				}
+		// The name must be unique per call: repositories.Roles.Create
+		// upserts on (name), so a shared literal here would make every
+		// AttributeToEmails call merge its permissions onto the same role,
+		// leaking grants across unrelated calls.
+		roleName := fmt.Sprintf("attribute-to-emails-%s", uuid.NewString())
+		role := &models.Role{ID: uuid.NewString(), Name: roleName}
+		if err := repo.Roles.Create(role); err != nil {
+			return err
+		}
+		for _, permission := range pa.Permissions {
+			permission.RoleID = role.ID
+			if err := repo.Permissions.Create(&permission); err != nil {
+				return err
+			}
+		}
+		for _, email := range pa.Emails {
+			rb := &models.RoleBinding{
+				ID:          uuid.NewString(),
+				RoleID:      role.ID,
+				SubjectType: models.SubjectTypes.Email,
+				SubjectID:   email,
+			}
+			if err := repo.RoleBindings.Create(rb); err != nil {
+				return err
 			}
 		}
 		return nil
 	})
 }
 
+// ContextsForPermission returns every ResourceHierarchy under which sa holds
+// a permission matching action over service (Service/Action "*" included),
+// so callers can scope a listing query to only what sa is allowed to see.
+func (ps permissions) ContextsForPermission(
+	sa *models.ServiceAccount, action models.Action, service string,
+) ([]models.ResourceHierarchy, error) {
+	saPermissions, err := ps.permissionsForServiceAccount(sa)
+	if err != nil {
+		return nil, err
+	}
+	rhs := []models.ResourceHierarchy{}
+	for _, p := range saPermissions {
+		if (p.Service != "*" && p.Service != service) ||
+			(p.Action != "*" && p.Action != action) {
+			continue
+		}
+		rhs = append(rhs, p.ResourceHierarchy)
+	}
+	return rhs, nil
+}
+
+// permissionsForServiceAccount resolves sa's effective permissions by
+// gathering role_bindings ⋈ roles ⋈ permissions for every binding
+// bindingsForServiceAccount finds, on top of the permissions still directly
+// attached to its base role, intersecting each permission's
+// ResourceHierarchy with its binding's Scope so a scoped delegation never
+// grants more than it was bound for.
+func (ps permissions) permissionsForServiceAccount(
+	sa *models.ServiceAccount,
+) ([]models.Permission, error) {
+	permissions, err := ps.repo.Permissions.ForRole(sa.BaseRoleID)
+	if err != nil {
+		return nil, err
+	}
+	bindings, err := bindingsForServiceAccount(ps.repo, sa)
+	if err != nil {
+		return nil, err
+	}
+	for _, rb := range bindings {
+		rolePermissions, err := ps.repo.Permissions.ForRole(rb.RoleID)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range rolePermissions {
+			if scoped, ok := rb.Intersect(p); ok {
+				permissions = append(permissions, scoped)
+			}
+		}
+	}
+	return permissions, nil
+}
+
 // NewPermissions ctor
 func NewPermissions(repo *repositories.All) Permissions {
 	return &permissions{repo: repo}
@@ -0,0 +1,64 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/topfreegames/Will.IAM/models"
+	"github.com/topfreegames/Will.IAM/repositories"
+)
+
+// RoleBindings define entrypoints for RoleBindings actions
+type RoleBindings interface {
+	Create(
+		roleID string, subjectType models.SubjectType, subjectID string,
+		scope models.ResourceHierarchy,
+	) (*models.RoleBinding, error)
+	List(roleID string) ([]models.RoleBinding, error)
+	Delete(id string) error
+	WithContext(context.Context) RoleBindings
+}
+
+type roleBindings struct {
+	repo *repositories.All
+	ctx  context.Context
+}
+
+func (rbs roleBindings) WithContext(ctx context.Context) RoleBindings {
+	return &roleBindings{rbs.repo.WithContext(ctx), ctx}
+}
+
+func (rbs roleBindings) Create(
+	roleID string, subjectType models.SubjectType, subjectID string,
+	scope models.ResourceHierarchy,
+) (*models.RoleBinding, error) {
+	if err := refuseBindingGuestOwnership(
+		rbs.repo, roleID, subjectType, subjectID,
+	); err != nil {
+		return nil, err
+	}
+	rb := &models.RoleBinding{
+		ID:          uuid.NewString(),
+		RoleID:      roleID,
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+		Scope:       scope,
+	}
+	if err := rbs.repo.RoleBindings.Create(rb); err != nil {
+		return nil, err
+	}
+	return rb, nil
+}
+
+func (rbs roleBindings) List(roleID string) ([]models.RoleBinding, error) {
+	return rbs.repo.RoleBindings.List(roleID)
+}
+
+func (rbs roleBindings) Delete(id string) error {
+	return rbs.repo.RoleBindings.Delete(id)
+}
+
+// NewRoleBindings ctor
+func NewRoleBindings(repo *repositories.All) RoleBindings {
+	return &roleBindings{repo: repo}
+}
@@ -0,0 +1,101 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/topfreegames/Will.IAM/models"
+	"github.com/topfreegames/Will.IAM/repositories"
+)
+
+// Roles define entrypoints for Roles actions
+type Roles interface {
+	EnsureBuiltins(ctx context.Context) error
+	List() ([]models.Role, error)
+	ListForContexts([]models.ResourceHierarchy) ([]models.Role, error)
+	GuestPermissions() ([]models.Permission, error)
+	WithContext(context.Context) Roles
+}
+
+type roles struct {
+	repo *repositories.All
+	ctx  context.Context
+}
+
+func (rs roles) WithContext(ctx context.Context) Roles {
+	return &roles{rs.repo.WithContext(ctx), ctx}
+}
+
+// EnsureBuiltins idempotently creates the root and guest roles if they
+// don't exist yet, so every installation can rely on them being present
+// without a manual bootstrap step. It also provisions the reserved guest
+// Service Account bound to the guest role, which is what withGuestFallback
+// actually attaches to unauthenticated requests.
+func (rs roles) EnsureBuiltins(ctx context.Context) error {
+	for _, name := range []string{models.RoleNames.Root, models.RoleNames.Guest} {
+		role, err := rs.repo.Roles.ForName(name)
+		if err != nil {
+			return err
+		}
+		if role == nil {
+			role = &models.Role{ID: uuid.NewString(), Name: name}
+			if err := rs.repo.Roles.Create(role); err != nil {
+				return err
+			}
+		}
+		if name == models.RoleNames.Guest {
+			if err := rs.ensureGuestServiceAccount(role.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ensureGuestServiceAccount creates the reserved guest Service Account,
+// bound to guestRoleID via BaseRoleID, if it doesn't already exist.
+func (rs roles) ensureGuestServiceAccount(guestRoleID string) error {
+	existing, err := rs.repo.ServiceAccounts.Get(models.GuestServiceAccountID)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != "" {
+		return nil
+	}
+	return rs.repo.ServiceAccounts.Create(&models.ServiceAccount{
+		ID:         models.GuestServiceAccountID,
+		Name:       "guest",
+		BaseRoleID: guestRoleID,
+	})
+}
+
+func (rs roles) List() ([]models.Role, error) {
+	return rs.repo.Roles.List()
+}
+
+// ListForContexts narrows List down to the roles reachable from rhs, the
+// same scoping usecases.ServiceAccounts.ListForContexts applies.
+func (rs roles) ListForContexts(
+	rhs []models.ResourceHierarchy,
+) ([]models.Role, error) {
+	return rs.repo.Roles.ListForResourceHierarchies(rhs)
+}
+
+// GuestPermissions lists every permission attached directly to the guest
+// role. Permissions the guest service account gets through role bindings
+// are not included.
+func (rs roles) GuestPermissions() ([]models.Permission, error) {
+	guest, err := rs.repo.Roles.ForName(models.RoleNames.Guest)
+	if err != nil {
+		return nil, err
+	}
+	if guest == nil {
+		return []models.Permission{}, nil
+	}
+	return rs.repo.Permissions.ForRole(guest.ID)
+}
+
+// NewRoles ctor
+func NewRoles(repo *repositories.All) Roles {
+	return &roles{repo: repo}
+}
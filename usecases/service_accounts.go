@@ -0,0 +1,362 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/topfreegames/Will.IAM/models"
+	"github.com/topfreegames/Will.IAM/repositories"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ServiceAccounts define entrypoints for ServiceAccounts actions
+type ServiceAccounts interface {
+	Get(string) (*models.ServiceAccount, error)
+	List() ([]models.ServiceAccount, error)
+	ListForContexts(
+		[]models.ResourceHierarchy,
+	) ([]models.ServiceAccount, error)
+	CreateOAuth2Type(name, email string) (*models.ServiceAccount, error)
+	CreateKeyPairType(name string) (*models.ServiceAccount, error)
+	RotateKeyPair(id string) (keyID, keySecret string, err error)
+	Authenticate(keyID, keySecret string) (*models.ServiceAccount, error)
+	Analyze(saID string) (*AccessReport, error)
+	CheckAccess(
+		saID string, permissionStrings []string,
+	) ([]AccessCheckResult, error)
+	WithContext(context.Context) ServiceAccounts
+}
+
+// AccessEntry is one deduplicated, wildcard-collapsed permission tuple in an
+// AccessReport, annotated with the roles/bindings that granted it.
+type AccessEntry struct {
+	Service           string                   `json:"service"`
+	Action            models.Action            `json:"action"`
+	OwnershipLevel    models.OwnershipLevel    `json:"ownershipLevel"`
+	ResourceHierarchy models.ResourceHierarchy `json:"resourceHierarchy"`
+	Sources           []string                 `json:"sources"`
+}
+
+// AccessReport is the result of Analyze(saID): sa's effective, deduplicated
+// access, plus which services it holds full access/ownership over.
+type AccessReport struct {
+	ServiceAccountID      string        `json:"serviceAccountId"`
+	Entries               []AccessEntry `json:"entries"`
+	FullAccessServices    []string      `json:"fullAccessServices"`
+	FullOwnershipServices []string      `json:"fullOwnershipServices"`
+}
+
+// AccessCheckResult is one line of a CheckAccess response.
+type AccessCheckResult struct {
+	Permission string   `json:"permission"`
+	Granted    bool     `json:"granted"`
+	Sources    []string `json:"sources"`
+}
+
+type serviceAccounts struct {
+	repo *repositories.All
+	ctx  context.Context
+}
+
+func (sas serviceAccounts) WithContext(ctx context.Context) ServiceAccounts {
+	return &serviceAccounts{sas.repo.WithContext(ctx), ctx}
+}
+
+func (sas serviceAccounts) Get(id string) (*models.ServiceAccount, error) {
+	return sas.repo.ServiceAccounts.Get(id)
+}
+
+func (sas serviceAccounts) List() ([]models.ServiceAccount, error) {
+	return sas.repo.ServiceAccounts.List()
+}
+
+// ListForContexts narrows List down to the service accounts reachable from
+// rhs, so handlers can push a caller's permission contexts into the query
+// instead of filtering an already-loaded, fully-visible list in memory.
+func (sas serviceAccounts) ListForContexts(
+	rhs []models.ResourceHierarchy,
+) ([]models.ServiceAccount, error) {
+	return sas.repo.ServiceAccounts.ListForResourceHierarchies(rhs)
+}
+
+func (sas serviceAccounts) CreateOAuth2Type(
+	name, email string,
+) (*models.ServiceAccount, error) {
+	sa := &models.ServiceAccount{Name: name, Email: email}
+	if err := sas.repo.ServiceAccounts.Create(sa); err != nil {
+		return nil, err
+	}
+	return sa, nil
+}
+
+func (sas serviceAccounts) CreateKeyPairType(
+	name string,
+) (*models.ServiceAccount, error) {
+	keyID, _, hash, err := buildKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	sa := &models.ServiceAccount{Name: name, KeyID: keyID, KeySecretHash: hash}
+	if err := sas.repo.ServiceAccounts.Create(sa); err != nil {
+		return nil, err
+	}
+	return sa, nil
+}
+
+// RotateKeyPair generates a fresh key pair for the service account id,
+// persists the bcrypt hash of the secret, and returns the plaintext secret
+// exactly once: it can't be recovered from the database after this call.
+func (sas serviceAccounts) RotateKeyPair(
+	id string,
+) (keyID, keySecret string, err error) {
+	sa, err := sas.repo.ServiceAccounts.Get(id)
+	if err != nil {
+		return "", "", err
+	}
+	keyID, keySecret, hash, err := buildKeyPair()
+	if err != nil {
+		return "", "", err
+	}
+	sa.KeyID = keyID
+	sa.KeySecretHash = hash
+	if err := sas.repo.ServiceAccounts.Update(sa); err != nil {
+		return "", "", err
+	}
+	return keyID, keySecret, nil
+}
+
+// Authenticate trades a keypair for the ServiceAccount it belongs to, the
+// lookup a keypair-exchange handler calls before minting that account a JWT.
+func (sas serviceAccounts) Authenticate(
+	keyID, keySecret string,
+) (*models.ServiceAccount, error) {
+	return sas.repo.ServiceAccounts.ForKeyPair(keyID, keySecret)
+}
+
+type sourcedPermission struct {
+	permission models.Permission
+	source     string
+}
+
+// effectivePermissions resolves sa's base-role permissions plus every
+// permission reachable through bindingsForServiceAccount, the same
+// resolution usecases.Permissions.permissionsForServiceAccount uses, tagging
+// each one with where it came from so Analyze can surface provenance.
+func (sas serviceAccounts) effectivePermissions(
+	sa *models.ServiceAccount,
+) ([]sourcedPermission, error) {
+	sourced := []sourcedPermission{}
+	basePermissions, err := sas.repo.Permissions.ForRole(sa.BaseRoleID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range basePermissions {
+		sourced = append(sourced, sourcedPermission{
+			p, "role:" + sa.BaseRoleID,
+		})
+	}
+	bindings, err := bindingsForServiceAccount(sas.repo, sa)
+	if err != nil {
+		return nil, err
+	}
+	for _, rb := range bindings {
+		rolePermissions, err := sas.repo.Permissions.ForRole(rb.RoleID)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range rolePermissions {
+			if scoped, ok := rb.Intersect(p); ok {
+				sourced = append(sourced, sourcedPermission{
+					scoped, "binding:" + rb.ID,
+				})
+			}
+		}
+	}
+	return sourced, nil
+}
+
+// Analyze reports every (Action, OwnershipLevel, ResourceHierarchy) tuple sa
+// effectively holds, deduplicated and with wildcard collapsing (x::y::*
+// absorbs x::y::z), alongside the roles/bindings that granted each one.
+func (sas serviceAccounts) Analyze(saID string) (*AccessReport, error) {
+	sa, err := sas.repo.ServiceAccounts.Get(saID)
+	if err != nil {
+		return nil, err
+	}
+	sourced, err := sas.effectivePermissions(sa)
+	if err != nil {
+		return nil, err
+	}
+	report := &AccessReport{ServiceAccountID: saID}
+	fullAccess := map[string]bool{}
+	fullOwnership := map[string]bool{}
+	byKey := map[string]*AccessEntry{}
+	order := []string{}
+	for _, sp := range sourced {
+		p := sp.permission
+		if p.HasServiceFullAccess() {
+			fullAccess[p.Service] = true
+		}
+		if p.HasServiceFullOwnership() {
+			fullOwnership[p.Service] = true
+		}
+		key := p.String()
+		if e, ok := byKey[key]; ok {
+			e.Sources = appendUniqueString(e.Sources, sp.source)
+			continue
+		}
+		byKey[key] = &AccessEntry{
+			Service:           p.Service,
+			Action:            p.Action,
+			OwnershipLevel:    p.OwnershipLevel,
+			ResourceHierarchy: p.ResourceHierarchy,
+			Sources:           []string{sp.source},
+		}
+		order = append(order, key)
+	}
+	entries := make([]AccessEntry, 0, len(order))
+	for _, key := range order {
+		entries = append(entries, *byKey[key])
+	}
+	report.Entries = collapseAccessEntries(entries)
+	for service := range fullAccess {
+		report.FullAccessServices = append(report.FullAccessServices, service)
+	}
+	for service := range fullOwnership {
+		report.FullOwnershipServices = append(
+			report.FullOwnershipServices, service,
+		)
+	}
+	return report, nil
+}
+
+// collapseAccessEntries drops any entry whose (Service, OwnershipLevel,
+// Action) tuple is already covered by a broader ResourceHierarchy in the
+// same set (x::y::* absorbs x::y::z), merging their Sources together. A
+// chain (x::y::z ⊂ x::y::* ⊂ x::*) always merges onto the entry that
+// actually survives (x::*), regardless of which entry is visited first,
+// since every merge is resolved through the absorption chain's root instead
+// of the immediate absorber.
+func collapseAccessEntries(entries []AccessEntry) []AccessEntry {
+	absorbedBy := make([]int, len(entries))
+	for i, e := range entries {
+		absorbedBy[i] = -1
+		for j, other := range entries {
+			if i == j || e.Service != other.Service ||
+				e.Action != other.Action ||
+				e.OwnershipLevel != other.OwnershipLevel {
+				continue
+			}
+			if other.ResourceHierarchy == e.ResourceHierarchy {
+				continue
+			}
+			if other.ResourceHierarchy.Contains(e.ResourceHierarchy) {
+				absorbedBy[i] = j
+				break
+			}
+		}
+	}
+	var root func(i int) int
+	root = func(i int) int {
+		if absorbedBy[i] == -1 {
+			return i
+		}
+		return root(absorbedBy[i])
+	}
+	for i, e := range entries {
+		if r := root(i); r != i {
+			entries[r].Sources = appendUniqueStrings(entries[r].Sources, e.Sources)
+		}
+	}
+	collapsed := make([]AccessEntry, 0, len(entries))
+	for i, e := range entries {
+		if root(i) == i {
+			collapsed = append(collapsed, e)
+		}
+	}
+	return collapsed
+}
+
+func appendUniqueString(sl []string, s string) []string {
+	for _, existing := range sl {
+		if existing == s {
+			return sl
+		}
+	}
+	return append(sl, s)
+}
+
+func appendUniqueStrings(sl []string, toAdd []string) []string {
+	for _, s := range toAdd {
+		sl = appendUniqueString(sl, s)
+	}
+	return sl
+}
+
+// CheckAccess reports, for each of permissionStrings, whether sa holds it
+// and which role/binding granted it, so tooling can audit a token's scope
+// the way credential-scope analyzers do for third-party API keys.
+func (sas serviceAccounts) CheckAccess(
+	saID string, permissionStrings []string,
+) ([]AccessCheckResult, error) {
+	sa, err := sas.repo.ServiceAccounts.Get(saID)
+	if err != nil {
+		return nil, err
+	}
+	sourced, err := sas.effectivePermissions(sa)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]AccessCheckResult, len(permissionStrings))
+	for i, str := range permissionStrings {
+		want, err := models.BuildPermission(str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid permission %q: %v", str, err)
+		}
+		result := AccessCheckResult{Permission: str}
+		for _, sp := range sourced {
+			if want.IsPresent([]models.Permission{sp.permission}) {
+				result.Granted = true
+				result.Sources = appendUniqueString(result.Sources, sp.source)
+			}
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// buildKeyPair generates a random keyID/keySecret pair and returns the
+// secret's bcrypt hash alongside it, so callers can persist the hash while
+// still handing the plaintext secret back to whoever asked for it.
+func buildKeyPair() (keyID, keySecret, keySecretHash string, err error) {
+	keyID, err = randomHex(8)
+	if err != nil {
+		return "", "", "", err
+	}
+	keySecret, err = randomHex(32)
+	if err != nil {
+		return "", "", "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword(
+		[]byte(keySecret), bcrypt.DefaultCost,
+	)
+	if err != nil {
+		return "", "", "", err
+	}
+	return keyID, keySecret, string(hash), nil
+}
+
+func randomHex(n int) (string, error) {
+	bts := make([]byte, n)
+	if _, err := rand.Read(bts); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bts), nil
+}
+
+// NewServiceAccounts ctor
+func NewServiceAccounts(repo *repositories.All) ServiceAccounts {
+	return &serviceAccounts{repo: repo}
+}
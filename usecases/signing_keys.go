@@ -0,0 +1,61 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/topfreegames/Will.IAM/models"
+	"github.com/topfreegames/Will.IAM/repositories"
+)
+
+// SigningKeys define entrypoints for administering Token signing keys
+type SigningKeys interface {
+	Add(
+		algorithm, publicKey, privateKey string,
+		notBefore, notAfter time.Time,
+	) (*models.SigningKey, error)
+	JWKS() ([]models.SigningKey, error)
+	WithContext(context.Context) SigningKeys
+}
+
+type signingKeys struct {
+	repo *repositories.All
+	ctx  context.Context
+}
+
+func (sks signingKeys) WithContext(ctx context.Context) SigningKeys {
+	return &signingKeys{sks.repo.WithContext(ctx), ctx}
+}
+
+// Add registers a new signing key. Administrators can POST one, wait for it
+// to propagate, then retire the old one while tokens it already signed
+// keep verifying until their own expiry.
+func (sks signingKeys) Add(
+	algorithm, publicKey, privateKey string,
+	notBefore, notAfter time.Time,
+) (*models.SigningKey, error) {
+	sk := &models.SigningKey{
+		ID:         uuid.NewString(),
+		Algorithm:  algorithm,
+		PublicKey:  publicKey,
+		PrivateKey: privateKey,
+		NotBefore:  notBefore,
+		NotAfter:   notAfter,
+	}
+	if err := sks.repo.SigningKeys.Create(sk); err != nil {
+		return nil, err
+	}
+	return sk, nil
+}
+
+// JWKS lists every known signing key's public half, keyed by id, for the
+// GET /.well-known/jwks.json endpoint.
+func (sks signingKeys) JWKS() ([]models.SigningKey, error) {
+	return sks.repo.SigningKeys.List()
+}
+
+// NewSigningKeys ctor
+func NewSigningKeys(repo *repositories.All) SigningKeys {
+	return &signingKeys{repo: repo}
+}
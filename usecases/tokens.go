@@ -0,0 +1,128 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/topfreegames/Will.IAM/models"
+	"github.com/topfreegames/Will.IAM/repositories"
+)
+
+// tokenTTL is how long a minted JWT is valid for before a service needs to
+// exchange for a new one.
+const tokenTTL = 15 * time.Minute
+
+// ErrNoKid is returned by Verify when a token has no `kid` header, so it
+// can't be matched back to the key that should verify it.
+var ErrNoKid = errors.New("token has no kid header")
+
+// ErrKeyNotFound is returned by Verify when a token's `kid` doesn't match
+// any stored SigningKey, e.g. because that key's row was deleted. A key
+// past its NotAfter is still found and still verifies.
+var ErrKeyNotFound = errors.New("signing key not found for kid")
+
+// TokenClaims are embedded in every Token Mint issues, letting downstream
+// services authorize offline without a round-trip to Will.IAM.
+type TokenClaims struct {
+	jwt.StandardClaims
+	ServiceAccountID string   `json:"said"`
+	BaseRoleID       string   `json:"rid"`
+	Scope            []string `json:"scope"`
+}
+
+// Tokens define entrypoints for minting and verifying service JWTs
+type Tokens interface {
+	Mint(sa *models.ServiceAccount, scope []string) (string, error)
+	Verify(token string) (*TokenClaims, error)
+	WithContext(context.Context) Tokens
+}
+
+type tokens struct {
+	repo *repositories.All
+	ctx  context.Context
+}
+
+func (ts tokens) WithContext(ctx context.Context) Tokens {
+	return &tokens{ts.repo.WithContext(ctx), ctx}
+}
+
+// Mint signs a short-lived JWT for sa after a successful keypair or OAuth2
+// exchange, stamping the signing key's ID into the `kid` header so the
+// verifier can look the right key up without trying every active one.
+func (ts tokens) Mint(sa *models.ServiceAccount, scope []string) (string, error) {
+	key, err := ts.repo.SigningKeys.Newest()
+	if err != nil {
+		return "", err
+	}
+	if key == nil {
+		return "", fmt.Errorf("no active signing key available")
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(key.PrivateKey))
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims := TokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:   sa.ID,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(tokenTTL).Unix(),
+		},
+		ServiceAccountID: sa.ID,
+		BaseRoleID:       sa.BaseRoleID,
+		Scope:            scope,
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(privateKey)
+}
+
+// Verify looks the token's `kid` up and checks the signature against that
+// specific SigningKey, so administrators can retire an old key and old
+// tokens keep verifying until they naturally expire.
+func (ts tokens) Verify(tokenString string) (*TokenClaims, error) {
+	claims := &TokenClaims{}
+	_, err := jwt.ParseWithClaims(
+		tokenString, claims,
+		func(t *jwt.Token) (interface{}, error) {
+			// Reject anything but RS256: jwks.json publishes every public
+			// key, so an alg:HS256 token signed with one of those keys as
+			// the HMAC secret would otherwise verify as genuine.
+			if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+				return nil, fmt.Errorf(
+					"unexpected signing method: %v", t.Header["alg"],
+				)
+			}
+			kid, ok := t.Header["kid"].(string)
+			if !ok || kid == "" {
+				return nil, ErrNoKid
+			}
+			key, err := ts.repo.SigningKeys.Get(kid)
+			if err != nil {
+				return nil, err
+			}
+			if key == nil {
+				return nil, ErrKeyNotFound
+			}
+			return jwt.ParseRSAPublicKeyFromPEM([]byte(key.PublicKey))
+		},
+	)
+	if err != nil {
+		// jwt-go wraps keyfunc errors in *jwt.ValidationError without an
+		// Unwrap, so ErrNoKid/ErrKeyNotFound need to be pulled back out of
+		// Inner for errors.Is to reach them.
+		if ve, ok := err.(*jwt.ValidationError); ok && ve.Inner != nil {
+			return nil, ve.Inner
+		}
+		return nil, err
+	}
+	return claims, nil
+}
+
+// NewTokens ctor
+func NewTokens(repo *repositories.All) Tokens {
+	return &tokens{repo: repo}
+}